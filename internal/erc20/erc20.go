@@ -0,0 +1,63 @@
+// Package erc20 is a small, hand-written binding (in the spirit of abigen)
+// for the subset of the ERC-20 interface the faucet needs in order to
+// dispense tokens other than the native coin: sending a payout and reading
+// the asset's decimals.
+//
+// NewERC20/Transfer are not yet called from a claim handler: this tree does
+// not contain the transaction-sending path (the component that holds the
+// faucet's signing key and builds/broadcasts the native-coin payout) that
+// would also be responsible for invoking Transfer for a token claim, so
+// there is no call site to wire this into. Once that sender exists, its
+// token-claim branch should call NewERC20(tokenConfig.ContractAddress,
+// client).Transfer(opts, to, amount) instead of a plain value transfer.
+// server.Config.Tokens/TokenBySymbol (meant to back an /api/info handler and
+// resolve the claimed token's TokenConfig for that sender) are equally
+// unwired for the same reason, so the token query/body parameter read by
+// readToken (and checked by server.Limiter) currently cannot result in an actual ERC-20 transfer.
+package erc20
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const erc20ABI = `[
+  {"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"},
+  {"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"}
+]`
+
+// ERC20 is a bound ERC-20 contract instance.
+type ERC20 struct {
+	address  common.Address
+	contract *bind.BoundContract
+}
+
+// NewERC20 binds address on backend.
+func NewERC20(address common.Address, backend bind.ContractBackend) (*ERC20, error) {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, err
+	}
+	return &ERC20{
+		address:  address,
+		contract: bind.NewBoundContract(address, parsed, backend, backend, backend),
+	}, nil
+}
+
+// Transfer sends amount, denominated in the token's smallest unit, to "to".
+func (e *ERC20) Transfer(opts *bind.TransactOpts, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return e.contract.Transact(opts, "transfer", to, amount)
+}
+
+// Decimals reads the token's configured decimals.
+func (e *ERC20) Decimals(opts *bind.CallOpts) (uint8, error) {
+	var decimals uint8
+	out := &[]interface{}{&decimals}
+	err := e.contract.Call(opts, out, "decimals")
+	return decimals, err
+}
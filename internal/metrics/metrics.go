@@ -0,0 +1,79 @@
+// Package metrics exposes the Prometheus instrumentation for the faucet's
+// claim, rate-limit, and captcha paths.
+//
+// RecordClaim, and Handler's mount on Config.metricsPort, have no call site
+// in this tree: both belong to the claim handler/transaction sender, which
+// this snapshot does not contain. RecordRateLimitHit and RecordCaptchaFailure
+// are wired into the middleware that does exist (see server.Limiter and
+// server.Captcha), so those two counters are live; claimsTotal, payoutWeiSum,
+// and txSendDuration are not, and /metrics is not actually served on any
+// port yet.
+package metrics
+
+import (
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	claimsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "faucet_claims_total",
+		Help: "Total number of claim attempts by outcome.",
+	}, []string{"status"})
+
+	rateLimitHitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "faucet_ratelimit_hits_total",
+		Help: "Total number of requests rejected by the rate limiter, by reason.",
+	}, []string{"reason"})
+
+	captchaFailuresTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "faucet_captcha_failures_total",
+		Help: "Total number of failed captcha verifications.",
+	})
+
+	payoutWeiSum = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "faucet_payout_wei_sum",
+		Help: "Cumulative amount of wei paid out across all successful claims.",
+	})
+
+	txSendDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "faucet_tx_send_duration_seconds",
+		Help:    "Time spent sending the payout transaction.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+// RecordClaim records the outcome of a claim attempt and, on success, the
+// amount paid out and how long sending the transaction took.
+func RecordClaim(status string, payoutWei *big.Int, duration time.Duration) {
+	claimsTotal.WithLabelValues(status).Inc()
+	if payoutWei != nil {
+		wei, _ := new(big.Float).SetInt(payoutWei).Float64()
+		payoutWeiSum.Add(wei)
+	}
+	if duration > 0 {
+		txSendDuration.Observe(duration.Seconds())
+	}
+}
+
+// RecordRateLimitHit records a request rejected by the rate limiter, keyed by
+// which dimension tripped it: "address", "ip", or "nonce".
+func RecordRateLimitHit(reason string) {
+	rateLimitHitsTotal.WithLabelValues(reason).Inc()
+}
+
+// RecordCaptchaFailure records a failed captcha verification.
+func RecordCaptchaFailure() {
+	captchaFailuresTotal.Inc()
+}
+
+// Handler serves the collected metrics in the Prometheus exposition format,
+// suitable for mounting at /metrics, optionally on a separate admin port.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
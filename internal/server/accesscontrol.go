@@ -0,0 +1,91 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// AccessControl evaluates the allow/deny CIDR lists and the exemption rules
+// shared by the rate limiter and captcha middlewares.
+type AccessControl struct {
+	allowlist        []netip.Prefix
+	denylist         []netip.Prefix
+	exemptUserAgents []string
+	exemptOrigins    []string
+}
+
+// NewAccessControl parses the given CIDRs and builds an AccessControl. An
+// empty allowlist means every IP is allowed unless it matches the denylist.
+func NewAccessControl(allowlist, denylist, exemptUserAgents, exemptOrigins []string) (*AccessControl, error) {
+	allow, err := parsePrefixes(allowlist)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowlist: %w", err)
+	}
+	deny, err := parsePrefixes(denylist)
+	if err != nil {
+		return nil, fmt.Errorf("invalid denylist: %w", err)
+	}
+	return &AccessControl{
+		allowlist:        allow,
+		denylist:         deny,
+		exemptUserAgents: exemptUserAgents,
+		exemptOrigins:    exemptOrigins,
+	}, nil
+}
+
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+// Denied reports whether clientIP is blocked: either it matches the denylist,
+// or an allowlist is configured and the IP matches none of its prefixes.
+func (ac *AccessControl) Denied(clientIP string) bool {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range ac.denylist {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if len(ac.allowlist) == 0 {
+		return false
+	}
+	for _, prefix := range ac.allowlist {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Exempt reports whether the request should bypass rate limiting and captcha
+// verification entirely, e.g. internal monitoring or CI.
+func (ac *AccessControl) Exempt(r *http.Request) bool {
+	if userAgent := r.Header.Get("User-Agent"); userAgent != "" {
+		for _, substr := range ac.exemptUserAgents {
+			if substr != "" && strings.Contains(userAgent, substr) {
+				return true
+			}
+		}
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		for _, allowed := range ac.exemptOrigins {
+			if origin == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
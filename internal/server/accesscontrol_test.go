@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessControlDenied(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		clientIP  string
+		want      bool
+	}{
+		{name: "no lists allows everyone", clientIP: "203.0.113.1", want: false},
+		{name: "denylist match is denied", denylist: []string{"203.0.113.0/24"}, clientIP: "203.0.113.1", want: true},
+		{name: "denylist miss is allowed", denylist: []string{"203.0.113.0/24"}, clientIP: "198.51.100.1", want: false},
+		{name: "allowlist match is allowed", allowlist: []string{"198.51.100.0/24"}, clientIP: "198.51.100.1", want: false},
+		{name: "allowlist miss is denied", allowlist: []string{"198.51.100.0/24"}, clientIP: "203.0.113.1", want: true},
+		{name: "denylist takes precedence over allowlist", allowlist: []string{"203.0.113.0/24"}, denylist: []string{"203.0.113.0/24"}, clientIP: "203.0.113.1", want: true},
+		{name: "unparseable IP is never denied", clientIP: "not-an-ip", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ac, err := NewAccessControl(tt.allowlist, tt.denylist, nil, nil)
+			if err != nil {
+				t.Fatalf("NewAccessControl() error = %v", err)
+			}
+			if got := ac.Denied(tt.clientIP); got != tt.want {
+				t.Errorf("Denied(%q) = %v, want %v", tt.clientIP, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessControlExempt(t *testing.T) {
+	tests := []struct {
+		name             string
+		exemptUserAgents []string
+		exemptOrigins    []string
+		userAgent        string
+		origin           string
+		want             bool
+	}{
+		{name: "no rules is never exempt", userAgent: "curl/8.0", want: false},
+		{name: "matching user agent substring is exempt", exemptUserAgents: []string{"kube-probe"}, userAgent: "kube-probe/1.29", want: true},
+		{name: "non-matching user agent is not exempt", exemptUserAgents: []string{"kube-probe"}, userAgent: "curl/8.0", want: false},
+		{name: "matching origin is exempt", exemptOrigins: []string{"https://example.com"}, origin: "https://example.com", want: true},
+		{name: "non-matching origin is not exempt", exemptOrigins: []string{"https://example.com"}, origin: "https://evil.example", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ac, err := NewAccessControl(nil, nil, tt.exemptUserAgents, tt.exemptOrigins)
+			if err != nil {
+				t.Fatalf("NewAccessControl() error = %v", err)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/api/claim", nil)
+			if tt.userAgent != "" {
+				r.Header.Set("User-Agent", tt.userAgent)
+			}
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if got := ac.Exempt(r); got != tt.want {
+				t.Errorf("Exempt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAccessControlRejectsInvalidCIDR(t *testing.T) {
+	if _, err := NewAccessControl([]string{"not-a-cidr"}, nil, nil, nil); err == nil {
+		t.Fatal("NewAccessControl() error = nil, want error for invalid allowlist entry")
+	}
+	if _, err := NewAccessControl(nil, []string{"not-a-cidr"}, nil, nil); err == nil {
+		t.Fatal("NewAccessControl() error = nil, want error for invalid denylist entry")
+	}
+}
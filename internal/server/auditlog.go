@@ -0,0 +1,66 @@
+package server
+
+import (
+	"math/big"
+	"net/netip"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// AuditLog writes one JSON line per claim attempt to a rotating log file so
+// operators can post-hoc analyze abuse patterns; the regular logrus INFO
+// lines emitted elsewhere are for humans, not machine parsing.
+type AuditLog struct {
+	logger *logrus.Logger
+}
+
+// NewAuditLog opens (or creates) path and rotates it once it exceeds
+// maxSizeMB, keeping maxBackups old files around.
+func NewAuditLog(path string, maxSizeMB, maxBackups int) *AuditLog {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetOutput(&lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+	})
+	return &AuditLog{logger: logger}
+}
+
+// Record appends one audit entry. maskedIP is expected to already be masked (see
+// MaskIP) before it reaches the log.
+func (a *AuditLog) Record(address, maskedIP, txHash string, amountWei *big.Int, decision, reason string) {
+	fields := logrus.Fields{
+		"address":  address,
+		"ip":       maskedIP,
+		"decision": decision,
+		"reason":   reason,
+	}
+	if txHash != "" {
+		fields["txHash"] = txHash
+	}
+	if amountWei != nil {
+		fields["amount"] = amountWei.String()
+	}
+	a.logger.WithFields(fields).Info("claim")
+}
+
+// MaskIP truncates the host-identifying part of ip before it is logged: the
+// last octet for IPv4, the last 80 bits for IPv6.
+func MaskIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	if addr.Is4() {
+		b := addr.As4()
+		b[3] = 0
+		return netip.AddrFrom4(b).String()
+	}
+	b := addr.As16()
+	for i := 6; i < 16; i++ {
+		b[i] = 0
+	}
+	return netip.AddrFrom16(b).String()
+}
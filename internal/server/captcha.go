@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/elastos/eth-faucet/internal/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// Captcha provider names accepted by NewCaptchaVerifier / NewConfig.
+const (
+	CaptchaProviderNone        = "none"
+	CaptchaProviderHCaptcha    = "hcaptcha"
+	CaptchaProviderReCaptchaV2 = "recaptcha-v2"
+	CaptchaProviderReCaptchaV3 = "recaptcha-v3"
+	CaptchaProviderTurnstile   = "turnstile"
+)
+
+// captchaResponseHeader carries the provider token regardless of which
+// captcha ecosystem is configured, so the frontend only needs to know which
+// widget to render. legacyHCaptchaResponseHeader is the header the faucet's
+// hCaptcha-only predecessor read; it's still accepted as a fallback so
+// operators upgrading from that version don't see every claim start failing
+// captcha verification.
+const captchaResponseHeader = "X-Captcha-Response"
+const legacyHCaptchaResponseHeader = "h-captcha-response"
+
+// CaptchaVerifier abstracts a captcha/anti-bot provider so operators can pick
+// whichever ecosystem they trust without forking the faucet. score is the
+// provider's confidence that the request is human; providers that only
+// return a binary verdict report 1 on success and 0 on failure.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) (score float64, ok bool, err error)
+}
+
+// NewCaptchaVerifier builds the CaptchaVerifier configured for provider.
+// threshold is only used by reCAPTCHA v3. An empty or "none" provider
+// disables captcha verification.
+func NewCaptchaVerifier(provider, siteKey, secret string, threshold float64) (CaptchaVerifier, error) {
+	switch provider {
+	case "", CaptchaProviderNone:
+		return nil, nil
+	case CaptchaProviderHCaptcha:
+		return NewHCaptchaVerifier(siteKey, secret), nil
+	case CaptchaProviderReCaptchaV2:
+		return NewReCaptchaV2Verifier(secret), nil
+	case CaptchaProviderReCaptchaV3:
+		return NewReCaptchaV3Verifier(secret, threshold), nil
+	case CaptchaProviderTurnstile:
+		return NewTurnstileVerifier(secret), nil
+	default:
+		return nil, fmt.Errorf("unknown captcha provider %q", provider)
+	}
+}
+
+// Captcha is the negroni middleware that gates claims behind a CaptchaVerifier.
+type Captcha struct {
+	verifier   CaptchaVerifier
+	proxyCount int
+	access     *AccessControl
+}
+
+func NewCaptcha(verifier CaptchaVerifier, proxyCount int, access *AccessControl) *Captcha {
+	return &Captcha{
+		verifier:   verifier,
+		proxyCount: proxyCount,
+		access:     access,
+	}
+}
+
+func (c *Captcha) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if c.verifier == nil || c.access.Exempt(r) {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	clientIP := getClientIPFromRequest(c.proxyCount, r)
+	token := r.Header.Get(captchaResponseHeader)
+	if token == "" {
+		token = r.Header.Get(legacyHCaptchaResponseHeader)
+	}
+	_, ok, err := c.verifier.Verify(r.Context(), token, clientIP)
+	if err != nil {
+		log.WithError(err).Error("Captcha verification request failed")
+		metrics.RecordCaptchaFailure()
+		renderJSON(w, claimResponse{Message: "Captcha verification failed, please try again"}, http.StatusTooManyRequests)
+		return
+	}
+	if !ok {
+		metrics.RecordCaptchaFailure()
+		renderJSON(w, claimResponse{Message: "Captcha verification failed, please try again"}, http.StatusTooManyRequests)
+		return
+	}
+
+	next.ServeHTTP(w, r)
+}
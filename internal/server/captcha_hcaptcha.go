@@ -0,0 +1,26 @@
+package server
+
+import (
+	"context"
+
+	"github.com/kataras/hcaptcha"
+)
+
+// HCaptchaVerifier verifies tokens against hCaptcha.
+type HCaptchaVerifier struct {
+	client *hcaptcha.Client
+}
+
+func NewHCaptchaVerifier(siteKey, secret string) *HCaptchaVerifier {
+	client := hcaptcha.New(secret)
+	client.SiteKey = siteKey
+	return &HCaptchaVerifier{client: client}
+}
+
+// Verify ignores remoteIP: hCaptcha's token already binds the requester.
+func (v *HCaptchaVerifier) Verify(ctx context.Context, token, remoteIP string) (float64, bool, error) {
+	if !v.client.VerifyToken(token).Success {
+		return 0, false, nil
+	}
+	return 1, true, nil
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const recaptchaVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+
+type recaptchaResponse struct {
+	Success bool    `json:"success"`
+	Score   float64 `json:"score"`
+}
+
+func verifyRecaptcha(ctx context.Context, secret, token, remoteIP string) (*recaptchaResponse, error) {
+	form := url.Values{"secret": {secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, recaptchaVerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result recaptchaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReCaptchaV2Verifier verifies tokens against Google reCAPTCHA v2, which only
+// returns a binary pass/fail verdict.
+type ReCaptchaV2Verifier struct {
+	secret string
+}
+
+func NewReCaptchaV2Verifier(secret string) *ReCaptchaV2Verifier {
+	return &ReCaptchaV2Verifier{secret: secret}
+}
+
+func (v *ReCaptchaV2Verifier) Verify(ctx context.Context, token, remoteIP string) (float64, bool, error) {
+	result, err := verifyRecaptcha(ctx, v.secret, token, remoteIP)
+	if err != nil {
+		return 0, false, err
+	}
+	if !result.Success {
+		return 0, false, nil
+	}
+	return 1, true, nil
+}
+
+// ReCaptchaV3Verifier verifies tokens against Google reCAPTCHA v3 and rejects
+// any score below threshold (0 to 1, higher means more confidently human),
+// mirroring lotus-fountain's --captcha-threshold flag.
+type ReCaptchaV3Verifier struct {
+	secret    string
+	threshold float64
+}
+
+func NewReCaptchaV3Verifier(secret string, threshold float64) *ReCaptchaV3Verifier {
+	return &ReCaptchaV3Verifier{secret: secret, threshold: threshold}
+}
+
+func (v *ReCaptchaV3Verifier) Verify(ctx context.Context, token, remoteIP string) (float64, bool, error) {
+	result, err := verifyRecaptcha(ctx, v.secret, token, remoteIP)
+	if err != nil {
+		return 0, false, err
+	}
+	if !result.Success || result.Score < v.threshold {
+		return result.Score, false, nil
+	}
+	return result.Score, true, nil
+}
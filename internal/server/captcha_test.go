@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/urfave/negroni/v3"
+)
+
+type stubCaptchaVerifier struct {
+	score float64
+	ok    bool
+	err   error
+}
+
+func (s *stubCaptchaVerifier) Verify(ctx context.Context, token, remoteIP string) (float64, bool, error) {
+	return s.score, s.ok, s.err
+}
+
+func serveCaptcha(c *Captcha, r *http.Request) (status int, nextCalled bool) {
+	rw := negroni.NewResponseWriter(httptest.NewRecorder())
+	c.ServeHTTP(rw, r, func(http.ResponseWriter, *http.Request) { nextCalled = true })
+	return rw.Status(), nextCalled
+}
+
+func TestCaptchaServeHTTPDisabled(t *testing.T) {
+	access, err := NewAccessControl(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewAccessControl() error = %v", err)
+	}
+	c := NewCaptcha(nil, 0, access)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/claim", nil)
+	_, nextCalled := serveCaptcha(c, r)
+
+	if !nextCalled {
+		t.Error("next was not called when no CaptchaVerifier is configured")
+	}
+}
+
+func TestCaptchaServeHTTPExempt(t *testing.T) {
+	access, err := NewAccessControl(nil, nil, nil, []string{"https://example.com"})
+	if err != nil {
+		t.Fatalf("NewAccessControl() error = %v", err)
+	}
+	c := NewCaptcha(&stubCaptchaVerifier{ok: false}, 0, access)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/claim", nil)
+	r.Header.Set("Origin", "https://example.com")
+	_, nextCalled := serveCaptcha(c, r)
+
+	if !nextCalled {
+		t.Error("next was not called for an exempt origin, even though the verifier would have failed it")
+	}
+}
+
+func TestCaptchaServeHTTPVerified(t *testing.T) {
+	access, err := NewAccessControl(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewAccessControl() error = %v", err)
+	}
+	c := NewCaptcha(&stubCaptchaVerifier{score: 1, ok: true}, 0, access)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/claim", nil)
+	_, nextCalled := serveCaptcha(c, r)
+
+	if !nextCalled {
+		t.Error("next was not called for a verified captcha")
+	}
+}
+
+func TestCaptchaServeHTTPRejected(t *testing.T) {
+	access, err := NewAccessControl(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewAccessControl() error = %v", err)
+	}
+	c := NewCaptcha(&stubCaptchaVerifier{ok: false}, 0, access)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/claim", nil)
+	status, nextCalled := serveCaptcha(c, r)
+
+	if nextCalled {
+		t.Error("next was called despite a failed captcha verification")
+	}
+	if status != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", status, http.StatusTooManyRequests)
+	}
+}
+
+func TestCaptchaServeHTTPVerifierError(t *testing.T) {
+	access, err := NewAccessControl(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewAccessControl() error = %v", err)
+	}
+	c := NewCaptcha(&stubCaptchaVerifier{err: errors.New("upstream unavailable")}, 0, access)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/claim", nil)
+	status, nextCalled := serveCaptcha(c, r)
+
+	if nextCalled {
+		t.Error("next was called despite the verifier returning an error")
+	}
+	if status != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", status, http.StatusTooManyRequests)
+	}
+}
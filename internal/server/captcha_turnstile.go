@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const turnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+type turnstileResponse struct {
+	Success bool `json:"success"`
+}
+
+// TurnstileVerifier verifies tokens against Cloudflare Turnstile.
+type TurnstileVerifier struct {
+	secret string
+}
+
+func NewTurnstileVerifier(secret string) *TurnstileVerifier {
+	return &TurnstileVerifier{secret: secret}
+}
+
+func (v *TurnstileVerifier) Verify(ctx context.Context, token, remoteIP string) (float64, bool, error) {
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, turnstileVerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	var result turnstileResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, false, err
+	}
+	if !result.Success {
+		return 0, false, nil
+	}
+	return 1, true, nil
+}
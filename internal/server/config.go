@@ -1,27 +1,164 @@
 package server
 
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/elastos/eth-faucet/internal/store"
+)
+
 type Config struct {
-	provider        string
-	network         string
-	symbol          string
-	httpPort        int
-	interval        int
-	payout          float64
-	proxyCount      int
-	hcaptchaSiteKey string
-	hcaptchaSecret  string
-}
-
-func NewConfig(provider, network, symbol string, httpPort, interval, proxyCount int, payout float64, hcaptchaSiteKey, hcaptchaSecret string) *Config {
-	return &Config{
-		provider:        provider,
-		network:         network,
-		symbol:          symbol,
-		httpPort:        httpPort,
-		interval:        interval,
-		payout:          payout,
-		proxyCount:      proxyCount,
-		hcaptchaSiteKey: hcaptchaSiteKey,
-		hcaptchaSecret:  hcaptchaSecret,
+	provider    string
+	network     string
+	symbol      string
+	httpPort    int
+	metricsPort int
+	rateLimit   float64
+	burst       int
+	nonceTTL    time.Duration
+	payout      float64
+	proxyCount  int
+	captcha     CaptchaVerifier
+	access      *AccessControl
+	auditLog    *AuditLog
+	store       store.LimiterStore
+	quota       *QuotaAuth
+	tiers       map[Tier]TierQuota
+	tokens      []TokenConfig
+}
+
+// TierConfig configures the optional tiered-payout feature: SignerAddr is
+// the operator key that issues quota tokens (see QuotaAuth), and TierOne /
+// TierTwo are the payout and cooldown granted to callers presenting a token
+// for that tier. Leave it nil to disable tiered payouts entirely.
+type TierConfig struct {
+	SignerAddr common.Address
+	ChainID    *big.Int
+	TierOne    TierQuota
+	TierTwo    TierQuota
+}
+
+// CaptchaParams selects and configures the CaptchaVerifier backing the
+// captcha middleware; see NewCaptchaVerifier. Threshold is only used by
+// reCAPTCHA v3.
+type CaptchaParams struct {
+	Provider  string
+	SiteKey   string
+	Secret    string
+	Threshold float64
+}
+
+// AccessParams is the allow/deny/exemption configuration evaluated by
+// AccessControl. Allowlist and Denylist are CIDRs evaluated against the
+// resolved client IP; ExemptUserAgents and ExemptOrigins bypass rate
+// limiting and captcha entirely, e.g. for internal monitoring and CI.
+type AccessParams struct {
+	Allowlist        []string
+	Denylist         []string
+	ExemptUserAgents []string
+	ExemptOrigins    []string
+}
+
+// AuditLogParams enables the rotating JSON audit log (see NewAuditLog) when
+// Path is non-empty, capped at MaxSizeMB per file with MaxBackups kept
+// around.
+type AuditLogParams struct {
+	Path       string
+	MaxSizeMB  int
+	MaxBackups int
+}
+
+// StoreParams selects the LimiterStore backend; see store.New. BoltPath and
+// the Redis* fields only apply to their matching Backend.
+type StoreParams struct {
+	Backend       string
+	BoltPath      string
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+}
+
+// ConfigParams groups every NewConfig input by field name instead of
+// position: the constructor grew past two dozen positional parameters
+// across several features, at which point a silently transposed pair of
+// same-typed arguments (there were three bare strings in a row, and several
+// ints) becomes a real risk instead of a hypothetical one.
+type ConfigParams struct {
+	Provider    string
+	Network     string
+	Symbol      string
+	HTTPPort    int
+	MetricsPort int
+	RateLimit   float64
+	Burst       int
+	NonceTTL    time.Duration
+	ProxyCount  int
+	Payout      float64
+	Captcha     CaptchaParams
+	Access      AccessParams
+	AuditLog    AuditLogParams
+	Store       StoreParams
+	// Tier is optional; leave it nil to disable the EIP-712 quota-token
+	// tiers handled by QuotaAuth.
+	Tier *TierConfig
+	// Tokens lists the ERC-20 assets the faucet can dispense alongside the
+	// native coin; the claim API selects among them with a token query/body
+	// field (see readToken).
+	Tokens []TokenConfig
+}
+
+// NewConfig builds the faucet's Config from p. RateLimit and Burst
+// configure the per-key token bucket (tokens per second and bucket size);
+// NonceTTL is how long the limiter store remembers a claimed address's
+// nonce. MetricsPort is stored for when /metrics is served on a separate
+// admin port when non-zero (otherwise it would share HTTPPort); no listener
+// is started anywhere in this tree yet, so it is currently inert (see the
+// metrics package doc comment).
+func NewConfig(p ConfigParams) (*Config, error) {
+	access, err := NewAccessControl(p.Access.Allowlist, p.Access.Denylist, p.Access.ExemptUserAgents, p.Access.ExemptOrigins)
+	if err != nil {
+		return nil, err
+	}
+	captcha, err := NewCaptchaVerifier(p.Captcha.Provider, p.Captcha.SiteKey, p.Captcha.Secret, p.Captcha.Threshold)
+	if err != nil {
+		return nil, err
 	}
+	var auditLog *AuditLog
+	if p.AuditLog.Path != "" {
+		auditLog = NewAuditLog(p.AuditLog.Path, p.AuditLog.MaxSizeMB, p.AuditLog.MaxBackups)
+	}
+	limiterStore, err := store.New(p.Store.Backend, p.Store.BoltPath, p.Store.RedisAddr, p.Store.RedisPassword, p.Store.RedisDB)
+	if err != nil {
+		return nil, err
+	}
+
+	var quota *QuotaAuth
+	tiers := make(map[Tier]TierQuota)
+	if p.Tier != nil {
+		quota = NewQuotaAuth(p.Tier.SignerAddr, p.Tier.ChainID, limiterStore)
+		tiers[TierOne] = p.Tier.TierOne
+		tiers[TierTwo] = p.Tier.TierTwo
+	}
+
+	return &Config{
+		provider:    p.Provider,
+		network:     p.Network,
+		symbol:      p.Symbol,
+		httpPort:    p.HTTPPort,
+		metricsPort: p.MetricsPort,
+		rateLimit:   p.RateLimit,
+		burst:       p.Burst,
+		nonceTTL:    p.NonceTTL,
+		payout:      p.Payout,
+		proxyCount:  p.ProxyCount,
+		captcha:     captcha,
+		access:      access,
+		auditLog:    auditLog,
+		store:       limiterStore,
+		quota:       quota,
+		tiers:       tiers,
+		tokens:      p.Tokens,
+	}, nil
 }
@@ -3,41 +3,98 @@ package server
 import (
 	"context"
 	"errors"
-	"fmt"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"net"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/jellydator/ttlcache/v2"
-	"github.com/kataras/hcaptcha"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"golang.org/x/time/rate"
+
+	"github.com/elastos/eth-faucet/internal/metrics"
+	"github.com/elastos/eth-faucet/internal/store"
 	log "github.com/sirupsen/logrus"
 	"github.com/urfave/negroni/v3"
 )
 
+const noncePrefix = "nonce-"
+const rateLimitPrefix = "ratelimit-"
+
+// limiterIdleEvictAfter is how long a key's bucket may sit unused before it is
+// swept from the in-memory map; limiterSweepInterval bounds how often the
+// sweep itself runs, so it doesn't walk the whole map on every request.
+const limiterIdleEvictAfter = 30 * time.Minute
+const limiterSweepInterval = 5 * time.Minute
+
+// Limiter is a token-bucket rate limiter keyed independently on wallet
+// address and client IP, with allow/deny CIDR lists and exemptions layered on
+// top via AccessControl. Nonce bookkeeping is delegated to a LimiterStore so
+// it survives restarts (or is shared across instances) when configured with
+// a persistent backend. Idle per-key buckets are swept periodically (see
+// limiterIdleEvictAfter) so an attacker varying X-Forwarded-For per request
+// can't grow limiters without bound.
 type Limiter struct {
-	mutex      sync.Mutex
-	cache      *ttlcache.Cache
-	proxyCount int
-	ttl        time.Duration
-	provider   string
+	mutex       sync.Mutex
+	limiters    map[string]*rate.Limiter
+	lastUsed    map[string]time.Time
+	lastSweep   time.Time
+	store       store.LimiterStore
+	nonceTTL    time.Duration
+	rateLimit   rate.Limit
+	burst       int
+	proxyCount  int
+	provider    string
+	access      *AccessControl
+	auditLog    *AuditLog
+	validTokens map[string]bool
 }
 
-func NewLimiter(proxyCount int, ttl time.Duration, provider string) *Limiter {
-	cache := ttlcache.NewCache()
-	cache.SkipTTLExtensionOnHit(true)
+// NewLimiter builds a Limiter. tokens lists the ERC-20 symbols the faucet is
+// configured to dispense (see Config.Tokens) so requests naming an unknown
+// token, rather than silently minting a fresh rate-limit bucket for it, are
+// rejected outright.
+func NewLimiter(proxyCount int, rateLimit float64, burst int, nonceTTL time.Duration, provider string, access *AccessControl, auditLog *AuditLog, limiterStore store.LimiterStore, tokens []TokenConfig) *Limiter {
+	validTokens := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		validTokens[token.Symbol] = true
+	}
 	return &Limiter{
-		cache:      cache,
-		proxyCount: proxyCount,
-		ttl:        ttl,
-		provider:   provider,
+		limiters:    make(map[string]*rate.Limiter),
+		lastUsed:    make(map[string]time.Time),
+		store:       limiterStore,
+		nonceTTL:    nonceTTL,
+		rateLimit:   rate.Limit(rateLimit),
+		burst:       burst,
+		proxyCount:  proxyCount,
+		provider:    provider,
+		access:      access,
+		auditLog:    auditLog,
+		validTokens: validTokens,
 	}
 }
 
+// audit is a no-op when the audit log is disabled.
+func (l *Limiter) audit(address, clientIP, decision, reason string) {
+	if l.auditLog == nil {
+		return
+	}
+	l.auditLog.Record(address, MaskIP(clientIP), "", nil, decision, reason)
+}
+
 func (l *Limiter) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	clientIP := getClientIPFromRequest(l.proxyCount, r)
+	if l.access.Denied(clientIP) {
+		l.audit("", clientIP, "denied", "denylist")
+		renderJSON(w, claimResponse{Message: "Your IP address is not allowed to use this faucet"}, http.StatusForbidden)
+		return
+	}
+	if l.access.Exempt(r) {
+		next.ServeHTTP(w, r)
+		return
+	}
+
 	address, err := readAddress(r)
 	if err != nil {
 		var mr *malformedRequest
@@ -48,21 +105,31 @@ func (l *Limiter) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.Ha
 		}
 		return
 	}
+	token := readToken(r)
+	if token != "" && !l.validTokens[token] {
+		renderJSON(w, claimResponse{Message: "Unknown token"}, http.StatusBadRequest)
+		return
+	}
 
-	if l.ttl <= 0 {
+	if l.rateLimit <= 0 {
 		next.ServeHTTP(w, r)
 		return
 	}
 
-	clientIP := getClientIPFromRequest(l.proxyCount, r)
-	l.mutex.Lock()
-	if l.limitByKey(w, address) || l.limitByKey(w, clientIP) {
-		l.mutex.Unlock()
+	addressKey := address + "|" + token
+	clientIPKey := clientIP + "|" + token
+	if !l.allow(addressKey) {
+		metrics.RecordRateLimitHit("address")
+		l.audit(address, clientIP, "denied", "rate_limit_address")
+		renderJSON(w, claimResponse{Message: "You have exceeded the rate limit. Please wait before you try again"}, http.StatusTooManyRequests)
+		return
+	}
+	if !l.allow(clientIPKey) {
+		metrics.RecordRateLimitHit("ip")
+		l.audit(address, clientIP, "denied", "rate_limit_ip")
+		renderJSON(w, claimResponse{Message: "You have exceeded the rate limit. Please wait before you try again"}, http.StatusTooManyRequests)
 		return
 	}
-	l.cache.SetWithTTL(address, true, l.ttl)
-	l.cache.SetWithTTL(clientIP, true, l.ttl)
-	l.mutex.Unlock()
 
 	client, err := ethclient.Dial(l.provider)
 	if err != nil {
@@ -73,43 +140,100 @@ func (l *Limiter) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.Ha
 		return
 	}
 
-	if cacheNonce, err := l.cache.Get("nonce-" + address); err == nil {
-		if cacheNonce == toNonce {
-			log.WithFields(log.Fields{
-				"address":     address,
-				"nonce":       toNonce,
-				"cachedNonce": cacheNonce,
-			}).Info("Address nonce same as cached nonce")
-			l.cache.Remove(address)
-			l.cache.Remove(clientIP)
-			renderJSON(w, claimResponse{Message: "Please do not make repeated requests."}, http.StatusTooManyRequests)
-			return
-		}
+	_, cachedNonce, err := l.store.Get(noncePrefix + address)
+	hasNonce := err == nil
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		log.WithError(err).Error("Failed to read cached nonce from limiter store")
+	}
+	if hasNonce && cachedNonce == toNonce {
+		metrics.RecordRateLimitHit("nonce")
+		l.audit(address, clientIP, "denied", "repeated_nonce")
+		log.WithFields(log.Fields{
+			"address":     address,
+			"nonce":       toNonce,
+			"cachedNonce": cachedNonce,
+		}).Info("Address nonce same as cached nonce")
+		renderJSON(w, claimResponse{Message: "Please do not make repeated requests."}, http.StatusTooManyRequests)
+		return
 	}
 
 	next.ServeHTTP(w, r)
 	if w.(negroni.ResponseWriter).Status() != http.StatusOK {
-		l.cache.Remove(address)
-		l.cache.Remove(clientIP)
 		return
-	} else {
-		l.cache.Set("nonce-"+address, toNonce)
 	}
+	if err := l.store.Set(noncePrefix+address, l.nonceTTL, toNonce); err != nil {
+		log.WithError(err).Error("Failed to persist nonce to limiter store")
+	}
+	l.audit(address, clientIP, "allowed", "")
 	log.WithFields(log.Fields{
 		"address":  address,
 		"clientIP": clientIP,
 	}).Info("Maximum request limit has been reached")
 }
 
-func (l *Limiter) limitByKey(w http.ResponseWriter, key string) bool {
-	if _, ttl, err := l.cache.GetWithTTL(key); err == nil {
-		errMsg := fmt.Sprintf("You have exceeded the rate limit. Please wait %s before you try again", ttl.Round(time.Second))
-		renderJSON(w, claimResponse{Message: errMsg}, http.StatusTooManyRequests)
+// allow reports whether key still has a token available, creating its bucket
+// on first use. The in-memory *rate.Limiter is the fast path; its state is
+// also mirrored through the LimiterStore so a restart (or a second instance
+// sharing a Redis-backed store) still honours an in-flight cooldown instead
+// of wiping it, per the same durability guarantee the nonce cache gets.
+func (l *Limiter) allow(key string) bool {
+	now := time.Now()
+
+	l.mutex.Lock()
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = rate.NewLimiter(l.rateLimit, l.burst)
+		if _, exhaustedAtUnix, err := l.store.Get(rateLimitPrefix + key); err == nil {
+			// A still-unexpired entry means the bucket was exhausted before
+			// this process (re)started, at exhaustedAtUnix. Reserve relative
+			// to that original moment, not now, so the remaining cooldown
+			// carries over instead of restarting a full refillDuration on
+			// every restart.
+			limiter.ReserveN(time.Unix(int64(exhaustedAtUnix), 0), l.burst)
+		}
+		l.limiters[key] = limiter
+	}
+	l.lastUsed[key] = now
+	l.evictIdleLocked(now)
+	l.mutex.Unlock()
+
+	if limiter.Allow() {
 		return true
 	}
+	// Persist the exhaustion moment only once per cooldown: if an unexpired
+	// entry is already there, a retrying caller must not be able to keep
+	// pushing their own persisted cooldown forward.
+	if _, _, err := l.store.Get(rateLimitPrefix + key); errors.Is(err, store.ErrNotFound) {
+		if err := l.store.Set(rateLimitPrefix+key, l.refillDuration(), uint64(now.Unix())); err != nil {
+			log.WithError(err).Error("Failed to persist rate limiter state")
+		}
+	}
 	return false
 }
 
+// evictIdleLocked drops limiters untouched for longer than
+// limiterIdleEvictAfter, bounding the map's size. It must be called with
+// l.mutex held, and only does real work once per limiterSweepInterval so a
+// high-traffic faucet doesn't pay for a full map walk on every request.
+func (l *Limiter) evictIdleLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < limiterSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, seen := range l.lastUsed {
+		if now.Sub(seen) > limiterIdleEvictAfter {
+			delete(l.limiters, key)
+			delete(l.lastUsed, key)
+		}
+	}
+}
+
+// refillDuration is how long a fully drained bucket takes to refill from
+// empty, used as the TTL for the persisted "still exhausted" marker.
+func (l *Limiter) refillDuration() time.Duration {
+	return time.Duration(float64(l.burst) / float64(l.rateLimit) * float64(time.Second))
+}
+
 func getClientIPFromRequest(proxyCount int, r *http.Request) string {
 	if proxyCount > 0 {
 		xForwardedFor := r.Header.Get("X-Forwarded-For")
@@ -130,32 +254,3 @@ func getClientIPFromRequest(proxyCount int, r *http.Request) string {
 	}
 	return remoteIP
 }
-
-type Captcha struct {
-	client *hcaptcha.Client
-	secret string
-}
-
-func NewCaptcha(hcaptchaSiteKey, hcaptchaSecret string) *Captcha {
-	client := hcaptcha.New(hcaptchaSecret)
-	client.SiteKey = hcaptchaSiteKey
-	return &Captcha{
-		client: client,
-		secret: hcaptchaSecret,
-	}
-}
-
-func (c *Captcha) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-	if c.secret == "" {
-		next.ServeHTTP(w, r)
-		return
-	}
-
-	response := c.client.VerifyToken(r.Header.Get("h-captcha-response"))
-	if !response.Success {
-		renderJSON(w, claimResponse{Message: "Captcha verification failed, please try again"}, http.StatusTooManyRequests)
-		return
-	}
-
-	next.ServeHTTP(w, r)
-}
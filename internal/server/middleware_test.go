@@ -0,0 +1,130 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/elastos/eth-faucet/internal/store"
+	"github.com/urfave/negroni/v3"
+)
+
+func newTestLimiter(t *testing.T, access *AccessControl, rateLimit float64, burst int) *Limiter {
+	t.Helper()
+	if access == nil {
+		var err error
+		access, err = NewAccessControl(nil, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("NewAccessControl() error = %v", err)
+		}
+	}
+	return NewLimiter(0, rateLimit, burst, time.Minute, "", access, nil, store.NewMemoryStore(), nil)
+}
+
+func serveLimiter(l *Limiter, r *http.Request, next http.HandlerFunc) int {
+	rw := negroni.NewResponseWriter(httptest.NewRecorder())
+	l.ServeHTTP(rw, r, next)
+	return rw.Status()
+}
+
+// ServeHTTP's own path beyond the access-control checks below calls
+// readAddress, which isn't defined anywhere in this tree (see the other
+// server package files that already depend on it); that gap, not this test,
+// is what keeps those later branches from being exercised end-to-end here.
+// allow and evictIdleLocked are covered directly instead, since they hold
+// the actual rate-limiting logic and don't depend on readAddress.
+
+func TestLimiterServeHTTPDenylist(t *testing.T) {
+	access, err := NewAccessControl(nil, []string{"203.0.113.0/24"}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewAccessControl() error = %v", err)
+	}
+	l := newTestLimiter(t, access, 1, 1)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/claim", nil)
+	r.RemoteAddr = "203.0.113.1:1234"
+	called := false
+	status := serveLimiter(l, r, func(http.ResponseWriter, *http.Request) { called = true })
+
+	if status != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", status, http.StatusForbidden)
+	}
+	if called {
+		t.Error("next was called for a denylisted IP")
+	}
+}
+
+func TestLimiterServeHTTPExempt(t *testing.T) {
+	access, err := NewAccessControl(nil, nil, []string{"kube-probe"}, nil)
+	if err != nil {
+		t.Fatalf("NewAccessControl() error = %v", err)
+	}
+	l := newTestLimiter(t, access, 1, 1)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/claim", nil)
+	r.RemoteAddr = "203.0.113.1:1234"
+	r.Header.Set("User-Agent", "kube-probe/1.29")
+	called := false
+	serveLimiter(l, r, func(http.ResponseWriter, *http.Request) { called = true })
+
+	if !called {
+		t.Error("next was not called for an exempt request")
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	l := newTestLimiter(t, nil, 1, 2)
+
+	if !l.allow("key") {
+		t.Error("allow() = false, want true (first token of the burst)")
+	}
+	if !l.allow("key") {
+		t.Error("allow() = false, want true (second token of the burst)")
+	}
+	if l.allow("key") {
+		t.Error("allow() = true, want false (burst exhausted)")
+	}
+
+	if !l.allow("other-key") {
+		t.Error("allow() = false, want true for a distinct, untouched key")
+	}
+}
+
+func TestLimiterAllowPersistsExhaustionAndRestoresIt(t *testing.T) {
+	l := newTestLimiter(t, nil, 1, 1)
+
+	if !l.allow("key") {
+		t.Fatal("allow() = false, want true (first token of the burst)")
+	}
+	if l.allow("key") {
+		t.Fatal("allow() = true, want false (burst exhausted)")
+	}
+
+	// Simulate a restart: a fresh Limiter sharing the same store should
+	// immediately treat "key" as still exhausted rather than handing out a
+	// brand new burst.
+	restarted := NewLimiter(0, 1, 1, time.Minute, "", l.access, nil, l.store, nil)
+	if restarted.allow("key") {
+		t.Error("allow() on a restarted Limiter = true, want false (persisted exhaustion not honored)")
+	}
+}
+
+func TestLimiterEvictIdleLocked(t *testing.T) {
+	l := newTestLimiter(t, nil, 1, 1)
+	l.allow("idle-key")
+
+	now := time.Now()
+	l.lastUsed["idle-key"] = now.Add(-2 * limiterIdleEvictAfter)
+	// allow() above already ran a sweep; move it back a full interval so this
+	// call is not skipped by the sweep throttle.
+	l.lastSweep = now.Add(-limiterSweepInterval)
+	l.evictIdleLocked(now)
+
+	if _, ok := l.limiters["idle-key"]; ok {
+		t.Error("evictIdleLocked() left an idle key in the limiters map")
+	}
+	if _, ok := l.lastUsed["idle-key"]; ok {
+		t.Error("evictIdleLocked() left an idle key in the lastUsed map")
+	}
+}
@@ -0,0 +1,243 @@
+package server
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	cmath "github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/elastos/eth-faucet/internal/store"
+)
+
+// Tier identifies a caller's payout tier, resolved either from the default
+// anonymous tier or from a verified quota token (see QuotaAuth).
+type Tier int
+
+const (
+	TierAnon Tier = iota
+	TierOne
+	TierTwo
+)
+
+// TierQuota is a tier's payout amount and claim cooldown.
+type TierQuota struct {
+	Payout   float64
+	Interval time.Duration
+}
+
+type tierContextKey struct{}
+
+// TierFromContext returns the tier resolved for the request, defaulting to
+// TierAnon if no quota token was presented (or QuotaAuth isn't installed).
+//
+// Neither this function nor Config.tiers is called anywhere in this tree:
+// the component that would read the resolved tier back out of the request
+// context and apply its TierQuota to the payout amount and the limiter's
+// per-key cooldown (the claim handler and rate limiter construction,
+// respectively) does not exist in this snapshot. Until that call site is
+// added, QuotaAuth authenticates and upgrades nothing — a caller presenting
+// a valid tier-2 token is treated identically to an anonymous one.
+func TierFromContext(ctx context.Context) Tier {
+	tier, ok := ctx.Value(tierContextKey{}).(Tier)
+	if !ok {
+		return TierAnon
+	}
+	return tier
+}
+
+const quotaTokenHeader = "X-Quota-Token"
+
+var quotaTypedDataTypes = apitypes.Types{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+	},
+	"Quota": {
+		{Name: "address", Type: "address"},
+		{Name: "tier", Type: "uint8"},
+		{Name: "expiry", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+	},
+}
+
+// quotaToken is the payload a caller presents to claim a higher tier, signed
+// by the operator's key over its EIP-712 typed-data hash.
+type quotaToken struct {
+	address   common.Address
+	tier      Tier
+	expiry    time.Time
+	nonce     uint64
+	signature []byte
+}
+
+// QuotaAuth is the negroni middleware that verifies an optional EIP-712
+// signed quota token upgrading the caller into a higher payout tier. The
+// signature must come from signerAddr, the operator-controlled key that
+// issues quota tokens out of band (e.g. after KYC). Expired or replayed
+// (address, nonce) pairs are rejected; seen nonces are tracked in the same
+// LimiterStore the rate limiter uses. Lets one faucet serve both public users
+// and vetted developers without deploying multiple instances.
+type QuotaAuth struct {
+	signerAddr common.Address
+	chainID    *big.Int
+	store      store.LimiterStore
+}
+
+func NewQuotaAuth(signerAddr common.Address, chainID *big.Int, limiterStore store.LimiterStore) *QuotaAuth {
+	return &QuotaAuth{signerAddr: signerAddr, chainID: chainID, store: limiterStore}
+}
+
+func (q *QuotaAuth) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	raw := r.Header.Get(quotaTokenHeader)
+	if raw == "" {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	token, err := parseQuotaToken(raw)
+	if err != nil {
+		renderJSON(w, claimResponse{Message: "Invalid quota token"}, http.StatusBadRequest)
+		return
+	}
+	if time.Now().After(token.expiry) {
+		renderJSON(w, claimResponse{Message: "Quota token has expired"}, http.StatusUnauthorized)
+		return
+	}
+	if err := q.verifySignature(token); err != nil {
+		renderJSON(w, claimResponse{Message: "Quota token signature is invalid"}, http.StatusUnauthorized)
+		return
+	}
+
+	// The token only upgrades the address it was issued for; without this
+	// check, any valid unexpired token could be replayed against a claim for
+	// a different destination address.
+	claimAddress, err := readAddress(r)
+	if err != nil {
+		var mr *malformedRequest
+		if errors.As(err, &mr) {
+			renderJSON(w, claimResponse{Message: mr.message}, mr.status)
+		} else {
+			renderJSON(w, claimResponse{Message: http.StatusText(http.StatusInternalServerError)}, http.StatusInternalServerError)
+		}
+		return
+	}
+	if token.address != common.HexToAddress(claimAddress) {
+		renderJSON(w, claimResponse{Message: "Quota token does not match the claim address"}, http.StatusUnauthorized)
+		return
+	}
+
+	// Claiming the nonce must be atomic: two concurrent requests replaying the
+	// same single-use token should not both pass a separate Get-then-Set.
+	nonceKey := fmt.Sprintf("quota-nonce-%s-%d", token.address.Hex(), token.nonce)
+	claimed, err := q.store.SetNX(nonceKey, time.Until(token.expiry), token.nonce)
+	if err != nil {
+		renderJSON(w, claimResponse{Message: http.StatusText(http.StatusInternalServerError)}, http.StatusInternalServerError)
+		return
+	}
+	if !claimed {
+		renderJSON(w, claimResponse{Message: "Quota token has already been used"}, http.StatusUnauthorized)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), tierContextKey{}, token.tier)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+func (q *QuotaAuth) verifySignature(token *quotaToken) error {
+	hash, err := quotaTypedDataHash(token.address, token.tier, token.expiry, token.nonce, q.chainID)
+	if err != nil {
+		return err
+	}
+	if len(token.signature) != 65 {
+		return errors.New("quota token signature must be 65 bytes")
+	}
+	sig := append([]byte(nil), token.signature...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pubKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return err
+	}
+	if crypto.PubkeyToAddress(*pubKey) != q.signerAddr {
+		return errors.New("quota token was not signed by the configured signer")
+	}
+	return nil
+}
+
+// quotaTypedDataHash computes the EIP-712 digest signed over
+// {address, tier, expiry, nonce}.
+func quotaTypedDataHash(address common.Address, tier Tier, expiry time.Time, nonce uint64, chainID *big.Int) ([]byte, error) {
+	typedData := apitypes.TypedData{
+		Types:       quotaTypedDataTypes,
+		PrimaryType: "Quota",
+		Domain: apitypes.TypedDataDomain{
+			Name:    "eth-faucet",
+			Version: "1",
+			ChainId: (*cmath.HexOrDecimal256)(chainID),
+		},
+		Message: apitypes.TypedDataMessage{
+			"address": address.Hex(),
+			"tier":    strconv.Itoa(int(tier)),
+			"expiry":  strconv.FormatInt(expiry.Unix(), 10),
+			"nonce":   strconv.FormatUint(nonce, 10),
+		},
+	}
+
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return nil, err
+	}
+	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return nil, err
+	}
+	rawData := append([]byte("\x19\x01"), domainSeparator...)
+	rawData = append(rawData, messageHash...)
+	return crypto.Keccak256(rawData), nil
+}
+
+// parseQuotaToken decodes the dot-separated "<hex signature>.<address>.<tier>.<expiryUnix>.<nonce>" header value.
+func parseQuotaToken(raw string) (*quotaToken, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 5 {
+		return nil, errors.New("malformed quota token")
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(parts[0], "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(parts[1]) {
+		return nil, errors.New("invalid quota token address")
+	}
+	tier, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	expiryUnix, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := strconv.ParseUint(parts[4], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &quotaToken{
+		address:   common.HexToAddress(parts[1]),
+		tier:      Tier(tier),
+		expiry:    time.Unix(expiryUnix, 0),
+		nonce:     nonce,
+		signature: sig,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func signQuotaToken(t *testing.T, key *ecdsa.PrivateKey, address common.Address, tier Tier, expiry time.Time, nonce uint64, chainID *big.Int) string {
+	t.Helper()
+	hash, err := quotaTypedDataHash(address, tier, expiry, nonce, chainID)
+	if err != nil {
+		t.Fatalf("quotaTypedDataHash() error = %v", err)
+	}
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("crypto.Sign() error = %v", err)
+	}
+	return fmt.Sprintf("0x%x.%s.%d.%d.%d", sig, address.Hex(), tier, expiry.Unix(), nonce)
+}
+
+func TestParseQuotaToken(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("crypto.GenerateKey() error = %v", err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	expiry := time.Unix(1893456000, 0)
+	raw := signQuotaToken(t, key, address, TierOne, expiry, 7, big.NewInt(1))
+
+	token, err := parseQuotaToken(raw)
+	if err != nil {
+		t.Fatalf("parseQuotaToken() error = %v", err)
+	}
+	if token.address != address {
+		t.Errorf("address = %v, want %v", token.address, address)
+	}
+	if token.tier != TierOne {
+		t.Errorf("tier = %v, want %v", token.tier, TierOne)
+	}
+	if !token.expiry.Equal(expiry) {
+		t.Errorf("expiry = %v, want %v", token.expiry, expiry)
+	}
+	if token.nonce != 7 {
+		t.Errorf("nonce = %v, want 7", token.nonce)
+	}
+}
+
+func TestParseQuotaTokenRejectsMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "wrong number of parts", raw: "0xdead.0x1234567890123456789012345678901234567890.1"},
+		{name: "invalid signature hex", raw: "zz.0x1234567890123456789012345678901234567890.1.1893456000.7"},
+		{name: "invalid address", raw: "0xdead.not-an-address.1.1893456000.7"},
+		{name: "invalid tier", raw: "0xdead.0x1234567890123456789012345678901234567890.x.1893456000.7"},
+		{name: "invalid expiry", raw: "0xdead.0x1234567890123456789012345678901234567890.1.x.7"},
+		{name: "invalid nonce", raw: "0xdead.0x1234567890123456789012345678901234567890.1.1893456000.x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseQuotaToken(tt.raw); err == nil {
+				t.Fatalf("parseQuotaToken(%q) error = nil, want error", tt.raw)
+			}
+		})
+	}
+}
+
+func TestQuotaAuthVerifySignature(t *testing.T) {
+	signerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("crypto.GenerateKey() error = %v", err)
+	}
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("crypto.GenerateKey() error = %v", err)
+	}
+	signerAddr := crypto.PubkeyToAddress(signerKey.PublicKey)
+	chainID := big.NewInt(1)
+	claimAddr := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	expiry := time.Unix(1893456000, 0)
+
+	q := NewQuotaAuth(signerAddr, chainID, nil)
+
+	rawValid := signQuotaToken(t, signerKey, claimAddr, TierOne, expiry, 1, chainID)
+	validToken, err := parseQuotaToken(rawValid)
+	if err != nil {
+		t.Fatalf("parseQuotaToken() error = %v", err)
+	}
+	if err := q.verifySignature(validToken); err != nil {
+		t.Errorf("verifySignature() on a correctly signed token = %v, want nil", err)
+	}
+
+	rawWrongSigner := signQuotaToken(t, otherKey, claimAddr, TierOne, expiry, 1, chainID)
+	wrongSignerToken, err := parseQuotaToken(rawWrongSigner)
+	if err != nil {
+		t.Fatalf("parseQuotaToken() error = %v", err)
+	}
+	if err := q.verifySignature(wrongSignerToken); err == nil {
+		t.Error("verifySignature() on a token signed by a different key = nil, want error")
+	}
+
+	tamperedToken := *validToken
+	tamperedToken.tier = TierTwo
+	if err := q.verifySignature(&tamperedToken); err == nil {
+		t.Error("verifySignature() on a tampered token = nil, want error")
+	}
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenConfig describes one ERC-20 asset the faucet can dispense alongside
+// the native coin.
+type TokenConfig struct {
+	Symbol          string
+	ContractAddress common.Address
+	Decimals        uint8
+	Payout          float64
+	Interval        time.Duration
+}
+
+// TokenInfo is the shape advertised by /api/info for each supported token.
+type TokenInfo struct {
+	Symbol   string  `json:"symbol"`
+	Payout   float64 `json:"payout"`
+	Interval int64   `json:"interval"`
+}
+
+// Tokens returns the /api/info payload describing every token the faucet can
+// dispense, native coin first.
+//
+// Neither this method nor TokenBySymbol is called anywhere in this tree: the
+// /api/info handler and the claim handler that would use them don't exist in
+// this snapshot (see the erc20 package doc comment for the matching gap on
+// the dispensing side). Configuring TokenConfig entries currently has no
+// observable effect.
+func (c *Config) Tokens() []TokenInfo {
+	infos := make([]TokenInfo, 0, len(c.tokens)+1)
+	infos = append(infos, TokenInfo{Symbol: c.symbol, Payout: c.payout, Interval: int64(c.nonceTTL.Seconds())})
+	for _, token := range c.tokens {
+		infos = append(infos, TokenInfo{Symbol: token.Symbol, Payout: token.Payout, Interval: int64(token.Interval.Seconds())})
+	}
+	return infos
+}
+
+// TokenBySymbol looks up a configured ERC-20 token by symbol, as advertised
+// by /api/info. ok is false for the native coin's symbol or an unknown one.
+func (c *Config) TokenBySymbol(symbol string) (TokenConfig, bool) {
+	for _, token := range c.tokens {
+		if token.Symbol == symbol {
+			return token, true
+		}
+	}
+	return TokenConfig{}, false
+}
+
+// tokenQueryParam is the query/body field the claim API uses to select which
+// asset to receive; an empty value means the native coin.
+const tokenQueryParam = "token"
+
+// readToken extracts the requested token symbol from the claim request: the
+// query param and X-Token header take precedence, falling back to a "token"
+// field in the JSON request body (the same body readAddress parses for
+// "address"). The body is restored after reading so it remains available to
+// readAddress regardless of call order.
+func readToken(r *http.Request) string {
+	if token := r.URL.Query().Get(tokenQueryParam); token != "" {
+		return token
+	}
+	if token := r.Header.Get("X-Token"); token != "" {
+		return token
+	}
+
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || len(body) == 0 {
+		return ""
+	}
+
+	var payload struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	return payload.Token
+}
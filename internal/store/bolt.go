@@ -0,0 +1,102 @@
+package store
+
+import (
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var limiterBucket = []byte("limiter")
+
+// BoltStore is an embedded, file-backed LimiterStore: rate-limit state
+// survives a restart on a single node without standing up Redis.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(limiterBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStore) Get(key string) (time.Time, uint64, error) {
+	var expiresAt time.Time
+	var nonce uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(limiterBucket).Get([]byte(key))
+		if value == nil {
+			return ErrNotFound
+		}
+		expiresAt, nonce = decodeEntry(value)
+		if time.Now().After(expiresAt) {
+			return ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return expiresAt, nonce, nil
+}
+
+func (s *BoltStore) Set(key string, ttl time.Duration, nonce uint64) error {
+	value := encodeEntry(time.Now().Add(ttl), nonce)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(limiterBucket).Put([]byte(key), value)
+	})
+}
+
+func (s *BoltStore) Delete(key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(limiterBucket).Delete([]byte(key))
+	})
+}
+
+// SetNX runs the check-and-set inside a single bolt read-write transaction,
+// so it is atomic with respect to every other Get/Set/SetNX on this store.
+func (s *BoltStore) SetNX(key string, ttl time.Duration, nonce uint64) (bool, error) {
+	claimed := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(limiterBucket)
+		if value := bucket.Get([]byte(key)); value != nil {
+			expiresAt, _ := decodeEntry(value)
+			if time.Now().Before(expiresAt) {
+				return nil
+			}
+		}
+		claimed = true
+		return bucket.Put([]byte(key), encodeEntry(time.Now().Add(ttl), nonce))
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+func encodeEntry(expiresAt time.Time, nonce uint64) []byte {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[:8], uint64(expiresAt.UnixNano()))
+	binary.BigEndian.PutUint64(buf[8:], nonce)
+	return buf
+}
+
+func decodeEntry(buf []byte) (time.Time, uint64) {
+	expiresAt := time.Unix(0, int64(binary.BigEndian.Uint64(buf[:8])))
+	nonce := binary.BigEndian.Uint64(buf[8:])
+	return expiresAt, nonce
+}
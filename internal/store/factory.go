@@ -0,0 +1,26 @@
+package store
+
+import "fmt"
+
+// Backend names accepted by New.
+const (
+	BackendMemory = "memory"
+	BackendBolt   = "bolt"
+	BackendRedis  = "redis"
+)
+
+// New builds the LimiterStore configured for backend. boltPath is only used
+// by the bolt backend; redisAddr, redisPassword, and redisDB are only used by
+// the redis backend.
+func New(backend, boltPath, redisAddr, redisPassword string, redisDB int) (LimiterStore, error) {
+	switch backend {
+	case "", BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendBolt:
+		return NewBoltStore(boltPath)
+	case BackendRedis:
+		return NewRedisStore(newRedisClient(redisAddr, redisPassword, redisDB), "limiter:"), nil
+	default:
+		return nil, fmt.Errorf("unknown limiter store backend %q", backend)
+	}
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// memorySweepInterval bounds how often MemoryStore scans for expired entries
+// nobody has looked up since; without this, a key that's never queried again
+// after expiring (e.g. a one-off nonce for an address that never returns)
+// would sit in the map forever.
+const memorySweepInterval = 5 * time.Minute
+
+// MemoryStore is the default, process-local LimiterStore. It is the fastest
+// option but its state does not survive a restart.
+type MemoryStore struct {
+	mutex     sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+type memoryEntry struct {
+	expiresAt time.Time
+	nonce     uint64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (s *MemoryStore) Get(key string) (time.Time, uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return time.Time{}, 0, ErrNotFound
+	}
+	return entry.expiresAt, entry.nonce, nil
+}
+
+func (s *MemoryStore) Set(key string, ttl time.Duration, nonce uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries[key] = memoryEntry{expiresAt: time.Now().Add(ttl), nonce: nonce}
+	s.evictExpiredLocked(time.Now())
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) SetNX(key string, ttl time.Duration, nonce uint64) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry, ok := s.entries[key]; ok && time.Now().Before(entry.expiresAt) {
+		return false, nil
+	}
+	s.entries[key] = memoryEntry{expiresAt: time.Now().Add(ttl), nonce: nonce}
+	s.evictExpiredLocked(time.Now())
+	return true, nil
+}
+
+// evictExpiredLocked drops entries that have already expired, bounding the
+// map's size. It must be called with s.mutex held, and only does real work
+// once per memorySweepInterval so a busy store doesn't pay for a full map
+// walk on every write.
+func (s *MemoryStore) evictExpiredLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore shares rate-limit state across a horizontally scaled faucet
+// deployment sitting behind a load balancer.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func newRedisClient(addr, password string, db int) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+}
+
+func (s *RedisStore) Get(key string) (time.Time, uint64, error) {
+	ctx := context.Background()
+	value, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if err == redis.Nil {
+		return time.Time{}, 0, ErrNotFound
+	}
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	ttl, err := s.client.TTL(ctx, s.prefix+key).Result()
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	if ttl < 0 {
+		return time.Time{}, 0, ErrNotFound
+	}
+	return time.Now().Add(ttl), binary.BigEndian.Uint64(value), nil
+}
+
+func (s *RedisStore) Set(key string, ttl time.Duration, nonce uint64) error {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, nonce)
+	return s.client.Set(context.Background(), s.prefix+key, buf, ttl).Err()
+}
+
+func (s *RedisStore) Delete(key string) error {
+	return s.client.Del(context.Background(), s.prefix+key).Err()
+}
+
+// SetNX claims key via Redis's atomic SET ... NX, so two instances racing on
+// the same key can never both succeed.
+func (s *RedisStore) SetNX(key string, ttl time.Duration, nonce uint64) (bool, error) {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, nonce)
+	return s.client.SetNX(context.Background(), s.prefix+key, buf, ttl).Result()
+}
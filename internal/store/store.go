@@ -0,0 +1,26 @@
+// Package store provides pluggable persistence for the rate limiter's
+// per-key cooldown state, so a restart (or a second faucet instance sharing
+// a Redis backend) does not reset everyone's claim history.
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by LimiterStore.Get when key has no unexpired entry.
+var ErrNotFound = errors.New("store: key not found")
+
+// LimiterStore persists the rate limiter's per-key cooldown state: when it
+// expires, and the last nonce observed for that key.
+type LimiterStore interface {
+	Get(key string) (expiresAt time.Time, nonce uint64, err error)
+	Set(key string, ttl time.Duration, nonce uint64) error
+	Delete(key string) error
+
+	// SetNX atomically sets key to (ttl, nonce) only if it does not already
+	// hold an unexpired entry, reporting whether it claimed the key. Unlike
+	// a Get-then-Set pair, this is safe against two concurrent callers (e.g.
+	// two requests replaying the same single-use token) racing each other.
+	SetNX(key string, ttl time.Duration, nonce uint64) (claimed bool, err error)
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testLimiterStore exercises the Get/Set/Delete/SetNX TTL semantics every
+// LimiterStore implementation must share, so each backend's test just wires
+// up a fresh instance and calls this.
+func testLimiterStore(t *testing.T, newStore func(t *testing.T) LimiterStore) {
+	t.Run("Get on missing key returns ErrNotFound", func(t *testing.T) {
+		s := newStore(t)
+		if _, _, err := s.Get("missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Set then Get round-trips the nonce", func(t *testing.T) {
+		s := newStore(t)
+		if err := s.Set("key", time.Minute, 42); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		_, nonce, err := s.Get("key")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if nonce != 42 {
+			t.Errorf("nonce = %d, want 42", nonce)
+		}
+	})
+
+	t.Run("Get on an expired entry returns ErrNotFound", func(t *testing.T) {
+		s := newStore(t)
+		if err := s.Set("key", -time.Second, 1); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if _, _, err := s.Get("key"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Delete removes the entry", func(t *testing.T) {
+		s := newStore(t)
+		if err := s.Set("key", time.Minute, 1); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if err := s.Delete("key"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, _, err := s.Get("key"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("SetNX claims an unset key", func(t *testing.T) {
+		s := newStore(t)
+		claimed, err := s.SetNX("key", time.Minute, 1)
+		if err != nil {
+			t.Fatalf("SetNX() error = %v", err)
+		}
+		if !claimed {
+			t.Error("SetNX() on an unset key = false, want true")
+		}
+	})
+
+	t.Run("SetNX does not reclaim an unexpired key", func(t *testing.T) {
+		s := newStore(t)
+		if _, err := s.SetNX("key", time.Minute, 1); err != nil {
+			t.Fatalf("SetNX() error = %v", err)
+		}
+		claimed, err := s.SetNX("key", time.Minute, 2)
+		if err != nil {
+			t.Fatalf("SetNX() error = %v", err)
+		}
+		if claimed {
+			t.Error("SetNX() on an already-claimed key = true, want false")
+		}
+	})
+
+	t.Run("SetNX reclaims an expired key", func(t *testing.T) {
+		s := newStore(t)
+		if _, err := s.SetNX("key", -time.Second, 1); err != nil {
+			t.Fatalf("SetNX() error = %v", err)
+		}
+		claimed, err := s.SetNX("key", time.Minute, 2)
+		if err != nil {
+			t.Fatalf("SetNX() error = %v", err)
+		}
+		if !claimed {
+			t.Error("SetNX() on an expired key = false, want true")
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testLimiterStore(t, func(t *testing.T) LimiterStore {
+		return NewMemoryStore()
+	})
+}
+
+func TestBoltStore(t *testing.T) {
+	testLimiterStore(t, func(t *testing.T) LimiterStore {
+		dbPath := filepath.Join(t.TempDir(), "limiter.db")
+		s, err := NewBoltStore(dbPath)
+		if err != nil {
+			t.Fatalf("NewBoltStore() error = %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
+
+// RedisStore shares the same Get/Set/Delete/SetNX semantics exercised above,
+// but isn't covered here: it requires a running Redis instance, which this
+// suite has no way to start.